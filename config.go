@@ -0,0 +1,226 @@
+// Copyright 2018 The Cacophony Project. All rights reserved.
+// Use of this source code is governed by the Apache License Version 2.0;
+// see the LICENSE file for further details.
+
+package window
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// configDateFormat is used for Schedule's From/To date range in a Config.
+const configDateFormat = "2006-01-02"
+
+// Config is the serializable form of a Window, suitable for storing in
+// device config files and round-tripping through JSON, YAML or TOML.
+// Its fields mirror the arguments and Options accepted by New.
+type Config struct {
+	Start     string          `json:"start" yaml:"start"`
+	End       string          `json:"end" yaml:"end"`
+	Latitude  float64         `json:"latitude" yaml:"latitude"`
+	Longitude float64         `json:"longitude" yaml:"longitude"`
+	Location  string          `json:"location,omitempty" yaml:"location,omitempty"`
+	SunEvent  string          `json:"sun_event,omitempty" yaml:"sun_event,omitempty"`
+	Schedule  *ScheduleConfig `json:"schedule,omitempty" yaml:"schedule,omitempty"`
+}
+
+// ScheduleConfig is the serializable form of a Schedule.
+type ScheduleConfig struct {
+	Weekdays  []int  `json:"weekdays,omitempty" yaml:"weekdays,omitempty"`
+	Months    []int  `json:"months,omitempty" yaml:"months,omitempty"`
+	MonthDays []int  `json:"month_days,omitempty" yaml:"month_days,omitempty"`
+	From      string `json:"from,omitempty" yaml:"from,omitempty"`
+	To        string `json:"to,omitempty" yaml:"to,omitempty"`
+}
+
+// Parse builds a Window from its JSON-encoded Config, as produced by
+// Window.MarshalJSON or Window.Config.
+func Parse(cfg []byte) (*Window, error) {
+	w := &Window{}
+	if err := json.Unmarshal(cfg, w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Config returns the serializable form of w.
+func (w *Window) Config() Config {
+	if w.NoWindow {
+		// start == end is exactly how New itself recognises a NoWindow,
+		// so any absolute time works here.
+		return Config{Start: hourMinuteZero, End: hourMinuteZero}
+	}
+
+	cfg := Config{
+		Start:     w.start.configString(),
+		End:       w.end.configString(),
+		Latitude:  w.Latitude,
+		Longitude: w.Longitude,
+		SunEvent:  w.sunEvent.name(),
+	}
+	if w.location != nil {
+		cfg.Location = w.location.String()
+	}
+	if w.schedule != nil {
+		cfg.Schedule = w.schedule.config()
+	}
+	return cfg
+}
+
+// MarshalJSON implements json.Marshaler.
+func (w *Window) MarshalJSON() ([]byte, error) {
+	return json.Marshal(w.Config())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (w *Window) UnmarshalJSON(data []byte) error {
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return err
+	}
+	return w.fromConfig(cfg)
+}
+
+// MarshalText implements encoding.TextMarshaler, which YAML and TOML
+// libraries fall back to for types that don't map directly onto a
+// struct. It encodes the same Config that MarshalJSON does.
+func (w *Window) MarshalText() ([]byte, error) {
+	return w.MarshalJSON()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, the counterpart to
+// MarshalText.
+func (w *Window) UnmarshalText(text []byte) error {
+	return w.UnmarshalJSON(text)
+}
+
+func (w *Window) fromConfig(cfg Config) error {
+	parsed, err := New(cfg.Start, cfg.End, cfg.Latitude, cfg.Longitude)
+	if err != nil {
+		return err
+	}
+	*w = *parsed
+	if w.NoWindow {
+		return nil
+	}
+
+	if cfg.Location != "" {
+		loc, err := time.LoadLocation(cfg.Location)
+		if err != nil {
+			return fmt.Errorf("invalid location %q: %w", cfg.Location, err)
+		}
+		w.location = loc
+	}
+
+	event, err := parseSunEvent(cfg.SunEvent)
+	if err != nil {
+		return err
+	}
+	w.sunEvent = event
+
+	if cfg.Schedule != nil {
+		schedule, err := cfg.Schedule.schedule()
+		if err != nil {
+			return err
+		}
+		w.schedule = schedule
+	}
+	return nil
+}
+
+// hourMinuteZero is an arbitrary absolute time used to represent a
+// NoWindow in a Config: New treats any equal start/end pair as NoWindow.
+const hourMinuteZero = "00:00"
+
+func (t *absOrRelTime) configString() string {
+	if t.Relative {
+		return t.RelativeDuration.String()
+	}
+	return t.Time.Format(hourMinuteFormat)
+}
+
+// name returns the Config representation of e: the empty string for the
+// default Sunrise event, one of the predefined twilight names, or
+// "custom:<degrees>" for a CustomElevation.
+func (e SunEvent) name() string {
+	switch e {
+	case Sunrise:
+		return ""
+	case CivilTwilight:
+		return "civil_twilight"
+	case NauticalTwilight:
+		return "nautical_twilight"
+	case AstronomicalTwilight:
+		return "astronomical_twilight"
+	}
+	return fmt.Sprintf("custom:%g", e.elevation)
+}
+
+func parseSunEvent(name string) (SunEvent, error) {
+	switch name {
+	case "", "sunrise":
+		return Sunrise, nil
+	case "civil_twilight":
+		return CivilTwilight, nil
+	case "nautical_twilight":
+		return NauticalTwilight, nil
+	case "astronomical_twilight":
+		return AstronomicalTwilight, nil
+	}
+	if deg, ok := strings.CutPrefix(name, "custom:"); ok {
+		elevation, err := strconv.ParseFloat(deg, 64)
+		if err != nil {
+			return SunEvent{}, fmt.Errorf("invalid sun_event %q: %w", name, err)
+		}
+		return CustomElevation(elevation), nil
+	}
+	return SunEvent{}, fmt.Errorf("unknown sun_event %q", name)
+}
+
+func (s *Schedule) config() *ScheduleConfig {
+	sc := &ScheduleConfig{}
+	for _, d := range s.Weekdays {
+		sc.Weekdays = append(sc.Weekdays, int(d))
+	}
+	for _, m := range s.Months {
+		sc.Months = append(sc.Months, int(m))
+	}
+	sc.MonthDays = append(sc.MonthDays, s.MonthDays...)
+	if !s.From.IsZero() {
+		sc.From = s.From.Format(configDateFormat)
+	}
+	if !s.To.IsZero() {
+		sc.To = s.To.Format(configDateFormat)
+	}
+	return sc
+}
+
+func (sc *ScheduleConfig) schedule() (*Schedule, error) {
+	s := &Schedule{}
+	for _, d := range sc.Weekdays {
+		s.Weekdays = append(s.Weekdays, time.Weekday(d))
+	}
+	for _, m := range sc.Months {
+		s.Months = append(s.Months, time.Month(m))
+	}
+	s.MonthDays = append(s.MonthDays, sc.MonthDays...)
+	if sc.From != "" {
+		from, err := time.Parse(configDateFormat, sc.From)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule.from %q: %w", sc.From, err)
+		}
+		s.From = from
+	}
+	if sc.To != "" {
+		to, err := time.Parse(configDateFormat, sc.To)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule.to %q: %w", sc.To, err)
+		}
+		s.To = to
+	}
+	return s, nil
+}
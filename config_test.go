@@ -0,0 +1,111 @@
+// Copyright 2018 The Cacophony Project. All rights reserved.
+// Use of this source code is governed by the Apache License Version 2.0;
+// see the LICENSE file for further details.
+
+package window
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMarshalRoundTrip(t *testing.T) {
+	w, err := New(mkTime(9, 0), mkTime(17, 0), testLatitude, testLongitude)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(w)
+	require.NoError(t, err)
+
+	parsed, err := Parse(data)
+	require.NoError(t, err)
+	assert.Equal(t, w.Config(), parsed.Config())
+
+	again, err := json.Marshal(parsed)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(data), string(again))
+}
+
+func TestConfigRoundTripPreservesRelativeDurationSign(t *testing.T) {
+	w, err := New("-1h30m", "2h", testLatitude, testLongitude)
+	require.NoError(t, err)
+
+	cfg := w.Config()
+	assert.Equal(t, "-1h30m0s", cfg.Start)
+	assert.Equal(t, "2h0m0s", cfg.End)
+
+	data, err := json.Marshal(w)
+	require.NoError(t, err)
+	parsed, err := Parse(data)
+	require.NoError(t, err)
+
+	assert.Equal(t, -90*time.Minute, parsed.start.RelativeDuration)
+	assert.Equal(t, 2*time.Hour, parsed.end.RelativeDuration)
+}
+
+func TestConfigRoundTripNoWindow(t *testing.T) {
+	w, err := New(mkTime(9, 0), mkTime(9, 0), 0, 0)
+	require.NoError(t, err)
+	require.True(t, w.NoWindow)
+
+	data, err := json.Marshal(w)
+	require.NoError(t, err)
+
+	parsed, err := Parse(data)
+	require.NoError(t, err)
+	assert.True(t, parsed.NoWindow)
+}
+
+func TestConfigRoundTripWithLocationSunEventAndSchedule(t *testing.T) {
+	auckland, err := time.LoadLocation("Pacific/Auckland")
+	require.NoError(t, err)
+
+	w, err := NewWithSchedule("-1h", "2h", testLatitude, testLongitude, Schedule{
+		Weekdays:  []time.Weekday{time.Saturday, time.Sunday},
+		Months:    []time.Month{time.June, time.July},
+		MonthDays: []int{1, 15},
+		From:      time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:        time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	WithLocation(auckland)(w)
+	WithSunEvent(CivilTwilight)(w)
+
+	data, err := json.Marshal(w)
+	require.NoError(t, err)
+
+	var cfg Config
+	require.NoError(t, json.Unmarshal(data, &cfg))
+	assert.Equal(t, "civil_twilight", cfg.SunEvent)
+	assert.Equal(t, "Pacific/Auckland", cfg.Location)
+	require.NotNil(t, cfg.Schedule)
+	assert.ElementsMatch(t, []int{6, 0}, cfg.Schedule.Weekdays)
+	assert.Equal(t, "2023-01-01", cfg.Schedule.From)
+	assert.Equal(t, "2023-12-31", cfg.Schedule.To)
+
+	parsed, err := Parse(data)
+	require.NoError(t, err)
+	assert.Equal(t, w.Config(), parsed.Config())
+}
+
+func TestConfigRoundTripCustomElevation(t *testing.T) {
+	w, err := New("-1h", "2h", testLatitude, testLongitude, WithSunEvent(CustomElevation(-10)))
+	require.NoError(t, err)
+
+	cfg := w.Config()
+	assert.Equal(t, "custom:-10", cfg.SunEvent)
+
+	data, err := json.Marshal(w)
+	require.NoError(t, err)
+	parsed, err := Parse(data)
+	require.NoError(t, err)
+	assert.Equal(t, CustomElevation(-10), parsed.sunEvent)
+}
+
+func TestParseRejectsUnknownSunEvent(t *testing.T) {
+	_, err := Parse([]byte(`{"start":"09:00","end":"17:00","sun_event":"golden_hour"}`))
+	assert.Error(t, err)
+}
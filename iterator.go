@@ -0,0 +1,109 @@
+// Copyright 2018 The Cacophony Project. All rights reserved.
+// Use of this source code is governed by the Apache License Version 2.0;
+// see the LICENSE file for further details.
+
+package window
+
+import "time"
+
+// Interval is a single occurrence of a Window, with an absolute start
+// and end time.
+type Interval struct {
+	Start, End time.Time
+}
+
+// Next finds the first occurrence of the window starting strictly
+// after after. It reuses the same day-walking logic as NextStart/NextEnd,
+// but is driven from an arbitrary anchor time rather than w.Now(), so
+// it can be used to enumerate a run of future (or past) occurrences.
+// ok is false if no occurrence could be found, which happens for a
+// schedule that never matches, or for a relative window whose sun
+// event doesn't occur for longer than Window is willing to scan.
+func (w *Window) Next(after time.Time) (Interval, bool) {
+	if w.NoWindow {
+		return Interval{Start: after}, true
+	}
+	start := w.nextStartFrom(after)
+	for i := 0; i < maxScheduleScanDays && !start.IsZero(); i++ {
+		// A start with no end (the sun doesn't reach the end's event the
+		// following day) is skipped in favour of the next start.
+		if end := w.endFollowing(start); !end.IsZero() {
+			return Interval{Start: start, End: end}, true
+		}
+		start = w.nextStartFrom(start)
+	}
+	return Interval{}, false
+}
+
+func (w *Window) nextStartFrom(anchor time.Time) time.Time {
+	if w.start.Relative {
+		return w.nextRelativeStartFrom(anchor)
+	}
+	return w.nextAbsTime(anchor, w.start.Time)
+}
+
+func (w *Window) previousStartFrom(anchor time.Time) time.Time {
+	if w.start.Relative {
+		return w.previousRelativeStartFrom(anchor)
+	}
+	return w.previousAbsTime(anchor, w.start.Time)
+}
+
+// Occurrences returns every occurrence of the window that overlaps
+// [from, to), each clipped to that range. A NoWindow is reported as a
+// single interval spanning the whole range. Degenerate relative events
+// (the sun doesn't rise or set that day, near polar day/night) are
+// skipped rather than reported as zero-length intervals.
+func (w *Window) Occurrences(from, to time.Time) []Interval {
+	if !to.After(from) {
+		return nil
+	}
+	if w.NoWindow {
+		return []Interval{{Start: from, End: to}}
+	}
+
+	var raw []Interval
+
+	// An occurrence may already be under way at `from`.
+	cursor := from
+	if start := w.previousStartFrom(from); !start.IsZero() {
+		if end := w.endFollowing(start); !end.IsZero() && end.After(from) {
+			raw = append(raw, Interval{Start: start, End: end})
+			cursor = end
+		}
+	}
+
+	// Bound the scan by the number of calendar days in [from, to), not by
+	// maxScheduleScanDays: that constant limits how far a single Next call
+	// walks looking for the next occurrence, not how many occurrences a
+	// long [from, to) range may contain. The margin still protects against
+	// a cursor that fails to advance.
+	maxIterations := int(to.Sub(from).Hours()/24) + maxScheduleScanDays
+	for i := 0; i < maxIterations; i++ {
+		next, ok := w.Next(cursor)
+		if !ok || !next.Start.Before(to) {
+			break
+		}
+		raw = append(raw, next)
+		if !next.End.After(cursor) {
+			break
+		}
+		cursor = next.End
+	}
+
+	occurrences := make([]Interval, 0, len(raw))
+	for _, iv := range raw {
+		occurrences = append(occurrences, clip(iv, from, to))
+	}
+	return occurrences
+}
+
+func clip(iv Interval, from, to time.Time) Interval {
+	if iv.Start.Before(from) {
+		iv.Start = from
+	}
+	if iv.End.After(to) {
+		iv.End = to
+	}
+	return iv
+}
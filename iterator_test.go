@@ -0,0 +1,94 @@
+// Copyright 2018 The Cacophony Project. All rights reserved.
+// Use of this source code is governed by the Apache License Version 2.0;
+// see the LICENSE file for further details.
+
+package window
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextFindsOccurrenceAfterAnchor(t *testing.T) {
+	w, err := New(mkTime(9, 0), mkTime(17, 0), 0, 0)
+	require.NoError(t, err)
+
+	anchor := time.Date(2017, 1, 2, 12, 0, 0, 0, time.UTC)
+	iv, ok := w.Next(anchor)
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2017, 1, 3, 9, 0, 0, 0, time.UTC), iv.Start)
+	assert.Equal(t, time.Date(2017, 1, 3, 17, 0, 0, 0, time.UTC), iv.End)
+}
+
+func TestOccurrencesIncludesIntervalAlreadyUnderWay(t *testing.T) {
+	// Crosses midnight: 22:00-06:00.
+	w, err := New(mkTime(22, 0), mkTime(6, 0), 0, 0)
+	require.NoError(t, err)
+
+	from := time.Date(2017, 1, 2, 1, 0, 0, 0, time.UTC) // inside the interval that started 2017-01-01 22:00
+	to := time.Date(2017, 1, 2, 23, 0, 0, 0, time.UTC)
+
+	occ := w.Occurrences(from, to)
+	require.Len(t, occ, 2)
+
+	// First is clipped at `from`, since it began the previous day.
+	assert.Equal(t, from, occ[0].Start)
+	assert.Equal(t, time.Date(2017, 1, 2, 6, 0, 0, 0, time.UTC), occ[0].End)
+
+	// Second begins within range and is clipped at `to`.
+	assert.Equal(t, time.Date(2017, 1, 2, 22, 0, 0, 0, time.UTC), occ[1].Start)
+	assert.Equal(t, to, occ[1].End)
+}
+
+func TestOccurrencesNoWindowIsSingleIntervalSpanningRange(t *testing.T) {
+	zero := time.Time{}.Format(hourMinuteFormat)
+	w, err := New(zero, zero, 0, 0)
+	require.NoError(t, err)
+	require.True(t, w.NoWindow)
+
+	from := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2017, 1, 8, 0, 0, 0, 0, time.UTC)
+
+	occ := w.Occurrences(from, to)
+	require.Len(t, occ, 1)
+	assert.Equal(t, from, occ[0].Start)
+	assert.Equal(t, to, occ[0].End)
+}
+
+func TestOccurrencesDoesNotTruncateRangesLongerThanMaxScheduleScanDays(t *testing.T) {
+	w, err := New(mkTime(9, 0), mkTime(17, 0), 0, 0)
+	require.NoError(t, err)
+
+	from := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, maxScheduleScanDays+30)
+
+	occ := w.Occurrences(from, to)
+	require.Len(t, occ, maxScheduleScanDays+30)
+	last := occ[len(occ)-1]
+	assert.Equal(t, to.AddDate(0, 0, -1), last.Start.Truncate(24*time.Hour))
+}
+
+func TestOccurrencesSkipsDegenerateDaysNearPolarNight(t *testing.T) {
+	// Tromso-like latitude: the sun doesn't rise at all for stretches
+	// of the Northern-hemisphere winter.
+	w, err := New("-1h", "2h", 69.6, 18.9)
+	require.NoError(t, err)
+
+	from := time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	daysInRange := int(to.Sub(from).Hours() / 24)
+
+	occ := w.Occurrences(from, to)
+	require.NotEmpty(t, occ)
+	// Some days in the range have no sunrise/sunset at all, so they
+	// must be skipped rather than turning up as degenerate intervals.
+	assert.Less(t, len(occ), daysInRange)
+	for _, iv := range occ {
+		assert.True(t, iv.End.After(iv.Start), "interval %v is not positive-length", iv)
+		assert.False(t, iv.Start.Before(from))
+		assert.False(t, iv.End.After(to))
+	}
+}
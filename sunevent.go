@@ -0,0 +1,48 @@
+// Copyright 2018 The Cacophony Project. All rights reserved.
+// Use of this source code is governed by the Apache License Version 2.0;
+// see the LICENSE file for further details.
+
+package window
+
+// SunEvent selects which point in the sun's descent/ascent
+// relativeSunriseOn and relativeSunsetOn are measured from, expressed as
+// the sun's elevation above the horizon in degrees. The zero value is
+// not a valid SunEvent; use Sunrise or one of the other predefined
+// events, or CustomElevation.
+type SunEvent struct {
+	elevation float64
+}
+
+var (
+	// Sunrise/sunset proper, accounting for atmospheric refraction and
+	// the apparent radius of the solar disc.
+	Sunrise = SunEvent{elevation: -0.833}
+
+	// CivilTwilight is reached when the sun is 6 degrees below the
+	// horizon, at which point there's enough light for most outdoor
+	// activities without artificial lighting.
+	CivilTwilight = SunEvent{elevation: -6}
+
+	// NauticalTwilight is reached when the sun is 12 degrees below the
+	// horizon, at which point the horizon is no longer visible.
+	NauticalTwilight = SunEvent{elevation: -12}
+
+	// AstronomicalTwilight is reached when the sun is 18 degrees below
+	// the horizon, after which the sky is fully dark.
+	AstronomicalTwilight = SunEvent{elevation: -18}
+)
+
+// CustomElevation returns a SunEvent for the sun at deg degrees above the
+// horizon (negative values are below the horizon).
+func CustomElevation(deg float64) SunEvent {
+	return SunEvent{elevation: deg}
+}
+
+// WithSunEvent changes which point in the sun's descent/ascent a
+// relative start or end is measured from. Without this option, New
+// behaves as it always has: relative times are measured from Sunrise.
+func WithSunEvent(event SunEvent) Option {
+	return func(w *Window) {
+		w.sunEvent = event
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2018 The Cacophony Project. All rights reserved.
+// Use of this source code is governed by the Apache License Version 2.0;
+// see the LICENSE file for further details.
+
+package window
+
+import (
+	"testing"
+	"time"
+
+	sunrise "github.com/nathan-osman/go-sunrise"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithSunEventCivilTwilightIsRoughlyHalfAnHourFromSunrise(t *testing.T) {
+	w, err := New("-1h", "2h", testLatitude, testLongitude, WithSunEvent(CivilTwilight))
+	require.NoError(t, err)
+	require.Equal(t, CivilTwilight, w.sunEvent)
+
+	dawn, dusk := sunrise.TimeOfElevation(testLatitude, testLongitude, CivilTwilight.elevation, 2000, 1, 2)
+	geometricSunrise, geometricSunset := sunrise.TimeOfElevation(testLatitude, testLongitude, Sunrise.elevation, 2000, 1, 2)
+
+	assert.InDelta(t, 30*time.Minute, geometricSunrise.Sub(dawn), float64(5*time.Minute))
+	assert.InDelta(t, 30*time.Minute, dusk.Sub(geometricSunset), float64(5*time.Minute))
+}
+
+func TestWithSunEventSkipsDaysThatNeverReachElevation(t *testing.T) {
+	// Svalbard, mid-winter: the sun doesn't get anywhere near civil
+	// twilight, let alone sunrise.
+	const latitude, longitude = 78.2, 15.6
+	w, err := New("-1h", "2h", latitude, longitude, WithSunEvent(CivilTwilight))
+	require.NoError(t, err)
+
+	_, ss := sunrise.TimeOfElevation(latitude, longitude, CivilTwilight.elevation, 2023, time.December, 15)
+	require.True(t, ss.IsZero(), "test fixture assumption: no civil twilight that day at this latitude")
+
+	assert.True(t, w.relativeSunsetOn(2023, time.December, 15).IsZero())
+}
+
+func TestCustomElevation(t *testing.T) {
+	event := CustomElevation(-10)
+	assert.Equal(t, SunEvent{elevation: -10}, event)
+}
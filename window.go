@@ -19,7 +19,7 @@ const (
 // between two times of day. If `start` is after `end` then the time
 // window is assumed to cross over midnight. If `start` and `end` are
 // the same then the window is always active.
-func New(start, end string, lat, long float64) (*Window, error) {
+func New(start, end string, lat, long float64, opts ...Option) (*Window, error) {
 	startTime, err := parseAbsOrRelField(start)
 	if err != nil {
 		return nil, err
@@ -33,13 +33,62 @@ func New(start, end string, lat, long float64) (*Window, error) {
 		return &Window{NoWindow: true}, nil
 	}
 
-	return &Window{
+	w := &Window{
 		start:     startTime,
 		end:       endTime,
 		Latitude:  lat,
 		Longitude: long,
+		sunEvent:  Sunrise,
 		Now:       time.Now,
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w, nil
+}
+
+// NewWithSchedule is like New but additionally restricts the window to
+// the weekdays, month-days, months and/or date range described by
+// schedule. Days that don't satisfy schedule are skipped entirely, as
+// if the window didn't occur on them at all.
+func NewWithSchedule(start, end string, lat, long float64, schedule Schedule) (*Window, error) {
+	return New(start, end, lat, long, WithSchedule(schedule))
+}
+
+// Option configures optional Window behaviour beyond the required
+// start/end/latitude/longitude passed to New.
+type Option func(*Window)
+
+// WithSchedule restricts the Window to the weekdays, month-days,
+// months and/or date range described by schedule.
+func WithSchedule(schedule Schedule) Option {
+	return func(w *Window) {
+		w.schedule = &schedule
+	}
+}
+
+// WithLocation evaluates the Window's absolute start/end times, and the
+// calendar day used to decide which days match its Schedule, in loc
+// rather than in w.Now()'s zone. This matters on devices with a
+// misconfigured system clock or that run in UTC: without it, an
+// absolute "15:04" time is interpreted in whatever zone Now() returns,
+// which may not be the zone the caller actually meant.
+func WithLocation(loc *time.Location) Option {
+	return func(w *Window) {
+		w.location = loc
+	}
+}
+
+// Schedule restricts a Window to a subset of calendar days, in addition
+// to its daily start/end times. Each non-empty field is a filter; a
+// day must satisfy all of them (and fall within From/To, if set) to be
+// considered part of the window.
+type Schedule struct {
+	Weekdays  []time.Weekday
+	Months    []time.Month
+	MonthDays []int
+	From      time.Time
+	To        time.Time
 }
 
 // Window represents a recurring window between two times of day.
@@ -51,11 +100,101 @@ type Window struct {
 	Latitude  float64
 	Longitude float64
 
+	schedule *Schedule
+	location *time.Location
+	sunEvent SunEvent
+
 	Now func() time.Time
 
 	NoWindow bool
 }
 
+// now returns the current time in w's configured Location, or in
+// w.Now()'s own zone if none was set with WithLocation.
+func (w *Window) now() time.Time {
+	return w.toLocal(w.Now())
+}
+
+// toLocal converts t into w's configured Location, or returns it
+// unchanged if none was set with WithLocation. It lets a caller (such
+// as WindowSet) evaluate w at an anchor time of its own choosing
+// without needing to override w.Now.
+func (w *Window) toLocal(t time.Time) time.Time {
+	if w.location != nil {
+		return t.In(w.location)
+	}
+	return t
+}
+
+// maxScheduleScanDays bounds how far Window will walk forward or
+// backward looking for a day that satisfies its schedule, so a
+// schedule that (through user error) never matches can't loop forever.
+const maxScheduleScanDays = 2 * 366
+
+// dayMatches reports whether t falls on a day allowed by w's schedule.
+// A Window with no schedule matches every day.
+func (w *Window) dayMatches(t time.Time) bool {
+	s := w.schedule
+	if s == nil {
+		return true
+	}
+	if !s.From.IsZero() && dateBefore(t, s.From) {
+		return false
+	}
+	if !s.To.IsZero() && dateAfter(t, s.To) {
+		return false
+	}
+	if len(s.Weekdays) > 0 && !weekdayIn(t.Weekday(), s.Weekdays) {
+		return false
+	}
+	if len(s.Months) > 0 && !monthIn(t.Month(), s.Months) {
+		return false
+	}
+	if len(s.MonthDays) > 0 && !intIn(t.Day(), s.MonthDays) {
+		return false
+	}
+	return true
+}
+
+func dateBefore(t, ref time.Time) bool {
+	ty, tm, td := t.Date()
+	ry, rm, rd := ref.Date()
+	return time.Date(ty, tm, td, 0, 0, 0, 0, time.UTC).Before(time.Date(ry, rm, rd, 0, 0, 0, 0, time.UTC))
+}
+
+func dateAfter(t, ref time.Time) bool {
+	ty, tm, td := t.Date()
+	ry, rm, rd := ref.Date()
+	return time.Date(ty, tm, td, 0, 0, 0, 0, time.UTC).After(time.Date(ry, rm, rd, 0, 0, 0, 0, time.UTC))
+}
+
+func weekdayIn(d time.Weekday, days []time.Weekday) bool {
+	for _, x := range days {
+		if x == d {
+			return true
+		}
+	}
+	return false
+}
+
+func monthIn(m time.Month, months []time.Month) bool {
+	for _, x := range months {
+		if x == m {
+			return true
+		}
+	}
+	return false
+}
+
+func intIn(n int, vals []int) bool {
+	for _, x := range vals {
+		if x == n {
+			return true
+		}
+	}
+	return false
+}
+
 func parseAbsOrRelField(timeStr string) (*absOrRelTime, error) {
 	t := &absOrRelTime{}
 
@@ -84,78 +223,155 @@ type absOrRelTime struct {
 
 // NextEnd will give the next time the window will end.
 func (w *Window) NextEnd() time.Time {
+	if w.NoWindow {
+		return time.Time{}
+	}
+	return w.nextEndFrom(w.now())
+}
+
+// nextEndFrom is the anchor-driven counterpart of NextEnd, used by
+// WindowSet so it can evaluate a child Window at a time of its
+// choosing without overriding the child's Now field.
+func (w *Window) nextEndFrom(anchor time.Time) time.Time {
+	if w.schedule != nil && w.activeAt(anchor) {
+		// The window is already under way, so its end is fixed by when it
+		// started, regardless of whether the continuation day itself
+		// matches w.schedule (see endFollowing). Scanning independently
+		// from today, as below, can walk past the ongoing interval's end
+		// to the next day that matches the schedule instead.
+		return w.endFollowing(w.previousStartFrom(anchor))
+	}
 	if w.end.Relative {
-		return w.nextRelativeEnd()
+		return w.nextRelativeEndFrom(anchor)
 	}
-	return nextAbsTime(w.Now(), w.end.Time)
+	return w.nextAbsTime(anchor, w.end.Time)
 }
 
 // NextStart will give the next time the windiw will start.
 func (w *Window) NextStart() time.Time {
-	if w.start.Relative {
-		return w.nextRelativeStart()
+	if w.NoWindow {
+		return time.Time{}
 	}
-	return nextAbsTime(w.Now(), w.start.Time)
+	return w.nextStartFrom(w.now())
 }
 
 // PreviousStart will give the time the window last started.
 func (w *Window) PreviousStart() time.Time {
-	if w.start.Relative {
-		return w.previousRelativeStart()
+	if w.NoWindow {
+		return time.Time{}
 	}
-	return nextAbsTime(w.Now().Add(-24*time.Hour), w.start.Time)
+	return w.previousStartFrom(w.now())
 }
 
+// relativeSunriseOn returns the configured end time relative to w's
+// sun event (Sunrise by default) on the given day, or the zero time if
+// the start isn't relative or the sun doesn't reach that event that day
+// (polar day/night).
 func (w *Window) relativeSunriseOn(year int, month time.Month, day int) time.Time {
 	if !w.start.Relative {
 		return time.Time{}
 	}
-	sr, _ := sunrise.SunriseSunset(w.Latitude, w.Longitude, year, month, day)
+	sr, _ := sunrise.TimeOfElevation(w.Latitude, w.Longitude, w.sunEvent.elevation, year, month, day)
+	if sr.IsZero() {
+		return time.Time{}
+	}
 	return sr.Add(w.end.RelativeDuration)
 }
 
+// relativeSunsetOn returns the configured start time relative to w's
+// sun event (Sunrise by default) on the given day, or the zero time if
+// the end isn't relative or the sun doesn't reach that event that day
+// (polar day/night).
 func (w *Window) relativeSunsetOn(year int, month time.Month, day int) time.Time {
 	if !w.end.Relative {
 		return time.Time{}
 	}
-	_, ss := sunrise.SunriseSunset(w.Latitude, w.Longitude, year, month, day)
+	_, ss := sunrise.TimeOfElevation(w.Latitude, w.Longitude, w.sunEvent.elevation, year, month, day)
+	if ss.IsZero() {
+		return time.Time{}
+	}
 	return ss.Add(w.start.RelativeDuration)
 }
 
-func (w *Window) nextRelativeEnd() time.Time {
-	now := w.Now()
-	t := w.relativeSunriseOn(now.Year(), now.Month(), now.Day())
-	if t.After(now) {
-		return t
+// nextRelativeEndFrom walks forward a day at a time from anchor,
+// skipping any day that doesn't satisfy w.schedule, until it finds the
+// next sunrise-relative end time that's after anchor.
+func (w *Window) nextRelativeEndFrom(anchor time.Time) time.Time {
+	day := anchor
+	for i := 0; i < maxScheduleScanDays; i++ {
+		if w.dayMatches(day) {
+			if t := w.relativeSunriseOn(day.Year(), day.Month(), day.Day()); t.After(anchor) {
+				return t
+			}
+		}
+		day = addDay(day)
 	}
-	return w.relativeSunriseOn(now.Year(), now.Month(), now.Day()+1)
+	return time.Time{}
 }
 
-func (w *Window) nextRelativeStart() time.Time {
-	now := w.Now()
-	t := w.relativeSunsetOn(now.Year(), now.Month(), now.Day())
-	if t.After(now) {
-		return t
+// nextRelativeStartFrom is the sunset-relative counterpart of
+// nextRelativeEndFrom.
+func (w *Window) nextRelativeStartFrom(anchor time.Time) time.Time {
+	day := anchor
+	for i := 0; i < maxScheduleScanDays; i++ {
+		if w.dayMatches(day) {
+			if t := w.relativeSunsetOn(day.Year(), day.Month(), day.Day()); t.After(anchor) {
+				return t
+			}
+		}
+		day = addDay(day)
 	}
-	return w.relativeSunsetOn(now.Year(), now.Month(), now.Day()+1)
+	return time.Time{}
 }
 
-func (w *Window) previousRelativeStart() time.Time {
-	now := w.Now()
-	t := w.relativeSunsetOn(now.Year(), now.Month(), now.Day())
-	if t.Before(now) {
-		return t
+func (w *Window) previousRelativeStartFrom(anchor time.Time) time.Time {
+	day := anchor
+	for i := 0; i < maxScheduleScanDays; i++ {
+		if w.dayMatches(day) {
+			if t := w.relativeSunsetOn(day.Year(), day.Month(), day.Day()); t.Before(anchor) {
+				return t
+			}
+		}
+		day = day.AddDate(0, 0, -1)
+	}
+	return time.Time{}
+}
+
+// nextAbsTime finds the next occurrence of the given time-of-day
+// strictly after now, walking forward a day at a time to skip days that don't
+// satisfy w.schedule.
+func (w *Window) nextAbsTime(now, absTime time.Time) time.Time {
+	if w.dayMatches(now) {
+		if candidate := setTimeHourAndMinute(now, absTime.Hour(), absTime.Minute()); candidate.After(now) {
+			return candidate
+		}
 	}
-	return w.relativeSunsetOn(now.Year(), now.Month(), now.Day()-1)
+	day := addDay(now)
+	for i := 0; i < maxScheduleScanDays; i++ {
+		if w.dayMatches(day) {
+			return setTimeHourAndMinute(day, absTime.Hour(), absTime.Minute())
+		}
+		day = addDay(day)
+	}
+	return time.Time{}
 }
 
-func nextAbsTime(now, absTime time.Time) time.Time {
-	absTime = setTimeHourAndMinute(now, absTime.Hour(), absTime.Minute())
-	if absTime.After(now) {
-		return absTime
+// previousAbsTime is the backward-scanning counterpart of nextAbsTime,
+// used to find the most recent occurrence of a time-of-day at or before now.
+func (w *Window) previousAbsTime(now, absTime time.Time) time.Time {
+	if w.dayMatches(now) {
+		if candidate := setTimeHourAndMinute(now, absTime.Hour(), absTime.Minute()); !candidate.After(now) {
+			return candidate
+		}
 	}
-	now = addDay(now)
-	return setTimeHourAndMinute(now, absTime.Hour(), absTime.Minute())
+	day := now.AddDate(0, 0, -1)
+	for i := 0; i < maxScheduleScanDays; i++ {
+		if w.dayMatches(day) {
+			return setTimeHourAndMinute(day, absTime.Hour(), absTime.Minute())
+		}
+		day = day.AddDate(0, 0, -1)
+	}
+	return time.Time{}
 }
 
 func setTimeHourAndMinute(t time.Time, hour, minute int) time.Time {
@@ -167,7 +383,40 @@ func (w *Window) Active() bool {
 	if w.NoWindow {
 		return true
 	}
-	return w.NextEnd().Before(w.NextStart())
+	return w.activeAt(w.now())
+}
+
+// activeAt is the anchor-driven counterpart of Active, used by
+// WindowSet so it can evaluate a child Window at a time of its
+// choosing without overriding the child's Now field.
+func (w *Window) activeAt(anchor time.Time) bool {
+	if w.schedule == nil {
+		return w.nextEndFrom(anchor).Before(w.nextStartFrom(anchor))
+	}
+	// With a schedule, nextEndFrom/nextStartFrom are independently
+	// scanned and may land on a day that isn't the direct continuation
+	// of the current interval, so pair the end explicitly with
+	// previousStartFrom.
+	start := w.previousStartFrom(anchor)
+	if start.IsZero() {
+		return false
+	}
+	return anchor.Before(w.endFollowing(start))
+}
+
+// endFollowing returns the end of the interval that began at start,
+// regardless of whether the day it falls on matches w.schedule: once a
+// window has started on a valid day, it runs to completion.
+func (w *Window) endFollowing(start time.Time) time.Time {
+	if w.end.Relative {
+		day := start.AddDate(0, 0, 1)
+		return w.relativeSunriseOn(day.Year(), day.Month(), day.Day())
+	}
+	end := setTimeHourAndMinute(start, w.end.Time.Hour(), w.end.Time.Minute())
+	if !end.After(start) {
+		end = addDay(end)
+	}
+	return end
 }
 
 // Until returns the duration until the next time window starts.
@@ -175,11 +424,15 @@ func (w *Window) Until() time.Duration {
 	if w.NoWindow || w.Active() {
 		return time.Duration(0)
 	}
-	return w.NextStart().Sub(w.Now())
+	return w.NextStart().Sub(w.now())
 }
 
+// addDay advances t to the same wall-clock time on the next calendar
+// day. It's calendar arithmetic rather than a fixed 24-hour Add so that
+// it lands on the correct date even when the next day is shortened or
+// lengthened by a DST transition.
 func addDay(t time.Time) time.Time {
-	return t.Add(24 * time.Hour)
+	return t.AddDate(0, 0, 1)
 }
 
 // UntilEnd returns the duration until the end of the time window.
@@ -187,23 +440,40 @@ func (w *Window) UntilEnd() time.Duration {
 	if w.NoWindow || !w.Active() {
 		return time.Duration(0)
 	}
-	return w.NextEnd().Sub(w.Now())
+	if w.schedule != nil {
+		return w.endFollowing(w.PreviousStart()).Sub(w.now())
+	}
+	return w.NextEnd().Sub(w.now())
 }
 
 // UntilNextInterval gets when the next interval starts.
 // Only works when window is currently active.
 func (w *Window) UntilNextInterval(interval time.Duration) time.Duration {
-	if w.NoWindow || !w.Active() {
+	if w.NoWindow {
 		return time.Duration(-1)
 	}
+	return w.untilNextIntervalAt(w.now(), interval)
+}
 
-	start := w.PreviousStart()
-	end := w.NextEnd()
-	elapsedTime := w.Now().Sub(start)
+// untilNextIntervalAt is the anchor-driven counterpart of
+// UntilNextInterval, used by WindowSet so it can evaluate a child
+// Window at a time of its choosing without overriding the child's Now
+// field.
+func (w *Window) untilNextIntervalAt(anchor time.Time, interval time.Duration) time.Duration {
+	if !w.activeAt(anchor) {
+		return time.Duration(-1)
+	}
+
+	start := w.previousStartFrom(anchor)
+	end := w.nextEndFrom(anchor)
+	if w.schedule != nil {
+		end = w.endFollowing(start)
+	}
+	elapsedTime := anchor.Sub(start)
 	nextInterval := start.Add(elapsedTime.Truncate(interval) + interval)
 
 	if end.After(nextInterval) {
-		return nextInterval.Sub(w.Now())
+		return nextInterval.Sub(anchor)
 	}
 
 	return time.Duration(-1)
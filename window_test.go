@@ -154,8 +154,8 @@ func TestSettingLatLong(t *testing.T) {
 	long := 80.0
 	w, err := New("1h", "1h", lat, long)
 	require.NoError(t, err)
-	assert.Equal(t, lat, w.latitude)
-	assert.Equal(t, long, w.longitude)
+	assert.Equal(t, lat, w.Latitude)
+	assert.Equal(t, long, w.Longitude)
 }
 
 func TestParsingOfWindow(t *testing.T) {
@@ -208,9 +208,9 @@ func TestSunriseSunset(t *testing.T) {
 	w.Now = notActiveNowDate
 	require.Equal(t, time.Duration(-1*time.Hour), w.start.RelativeDuration)
 	require.Equal(t, time.Duration(2*time.Hour), w.end.RelativeDuration)
-	_, todaySunset := sunrise.SunriseSunset(testLatitude, testLongitude, 2000, 1, 2)
-	tomorrowSunrise, tomorrowSunset := sunrise.SunriseSunset(testLatitude, testLongitude, 2000, 1, 3)
-	_, yesterdaySunset := sunrise.SunriseSunset(testLatitude, testLongitude, 2000, 1, 1)
+	_, todaySunset := sunrise.TimeOfElevation(testLatitude, testLongitude, Sunrise.elevation, 2000, 1, 2)
+	tomorrowSunrise, tomorrowSunset := sunrise.TimeOfElevation(testLatitude, testLongitude, Sunrise.elevation, 2000, 1, 3)
+	_, yesterdaySunset := sunrise.TimeOfElevation(testLatitude, testLongitude, Sunrise.elevation, 2000, 1, 1)
 
 	assert.Equal(t, yesterdaySunset.Add(-1*time.Hour), w.PreviousStart())
 	assert.Equal(t, todaySunset.Add(-1*time.Hour), w.NextStart())
@@ -232,6 +232,147 @@ func TestSunriseSunset(t *testing.T) {
 	assert.Equal(t, timeUntil14thInterval, w.UntilNextInterval(5*time.Minute))
 }
 
+func TestScheduleWeekdaysOnly(t *testing.T) {
+	// 2017-01-02 is a Monday, 2017-01-07 is a Saturday.
+	w, err := NewWithSchedule(mkTime(9, 0), mkTime(17, 0), 0, 0, Schedule{
+		Weekdays: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	})
+	require.NoError(t, err)
+
+	w.Now = mkNowDate(2017, 1, 2, 12, 0, time.UTC)
+	assert.True(t, w.Active())
+
+	w.Now = mkNowDate(2017, 1, 7, 12, 0, time.UTC)
+	assert.False(t, w.Active())
+	assert.Equal(t, mkNowDate(2017, 1, 9, 9, 0, time.UTC)(), w.NextStart())
+}
+
+func TestScheduleMonthRange(t *testing.T) {
+	// Only active during October-March.
+	w, err := NewWithSchedule(mkTime(22, 0), mkTime(6, 0), 0, 0, Schedule{
+		Months: []time.Month{time.October, time.November, time.December, time.January, time.February, time.March},
+	})
+	require.NoError(t, err)
+
+	w.Now = mkNowDate(2017, 7, 15, 23, 0, time.UTC)
+	assert.False(t, w.Active())
+	assert.Equal(t, mkNowDate(2017, 10, 1, 22, 0, time.UTC)(), w.NextStart())
+
+	w.Now = mkNowDate(2017, 1, 15, 23, 0, time.UTC)
+	assert.True(t, w.Active())
+}
+
+func TestScheduleCombinedMaskCrossingMidnight(t *testing.T) {
+	// Weekend-only window that crosses midnight.
+	w, err := NewWithSchedule(mkTime(22, 0), mkTime(6, 0), 0, 0, Schedule{
+		Weekdays: []time.Weekday{time.Saturday, time.Sunday},
+	})
+	require.NoError(t, err)
+
+	// Saturday 2017-01-07 23:00, inside the window that started at 22:00.
+	w.Now = mkNowDate(2017, 1, 7, 23, 0, time.UTC)
+	assert.True(t, w.Active())
+
+	// Sunday 2017-01-08 01:00 is still inside the Saturday-night window,
+	// even though Sunday itself would also be a valid start day.
+	w.Now = mkNowDate(2017, 1, 8, 1, 0, time.UTC)
+	assert.True(t, w.Active())
+
+	// Tuesday 2017-01-10 01:00: Monday isn't a valid start day, so the
+	// Sunday-night window (which ended Monday 06:00) is long over.
+	w.Now = mkNowDate(2017, 1, 10, 1, 0, time.UTC)
+	assert.False(t, w.Active())
+}
+
+func TestScheduleNextEndMatchesOngoingIntervalCrossingMidnight(t *testing.T) {
+	// Friday-only window that crosses midnight into Saturday, which isn't
+	// itself a valid start day.
+	w, err := NewWithSchedule(mkTime(22, 0), mkTime(6, 0), 0, 0, Schedule{
+		Weekdays: []time.Weekday{time.Friday},
+	})
+	require.NoError(t, err)
+
+	// 2017-01-06 is a Friday; 2017-01-07 (Saturday) 01:00 is inside the
+	// Friday-night window, which runs to Saturday 06:00.
+	w.Now = mkNowDate(2017, 1, 7, 1, 0, time.UTC)
+	require.True(t, w.Active())
+	assert.Equal(t, mkNowDate(2017, 1, 7, 6, 0, time.UTC)(), w.NextEnd())
+}
+
+func TestScheduleWithSunsetRelativeBranch(t *testing.T) {
+	// Sunset-1h to sunrise+2h, but only on weekdays.
+	w, err := NewWithSchedule("-1h", "2h", testLatitude, testLongitude, Schedule{
+		Weekdays: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	})
+	require.NoError(t, err)
+
+	_, satSunset := sunrise.TimeOfElevation(testLatitude, testLongitude, Sunrise.elevation, 2000, 1, 1)  // Saturday
+	monSunrise, _ := sunrise.TimeOfElevation(testLatitude, testLongitude, Sunrise.elevation, 2000, 1, 3) // Monday
+
+	// Saturday evening should be skipped entirely even though the sun sets.
+	w.Now = mkNowDate(2000, 1, 1, 21, 1, time.UTC) // 2000-01-01 is a Saturday
+	assert.False(t, w.Active())
+	assert.NotEqual(t, satSunset.Add(-1*time.Hour), w.NextStart())
+	assert.Equal(t, monSunrise.Add(2*time.Hour), w.NextEnd())
+}
+
+func TestWithLocationUsesGivenZoneRegardlessOfNow(t *testing.T) {
+	auckland, err := time.LoadLocation("Pacific/Auckland")
+	require.NoError(t, err)
+
+	w, err := New(mkTime(9, 0), mkTime(17, 0), 0, 0, WithLocation(auckland))
+	require.NoError(t, err)
+
+	// The clock reports UTC midnight, which is noon in Auckland (NZST,
+	// UTC+12, in the southern-hemisphere winter).
+	w.Now = func() time.Time {
+		return time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	assert.True(t, w.Active())
+	assert.Equal(t, time.Date(2023, 6, 1, 17, 0, 0, 0, auckland), w.NextEnd())
+	assert.Equal(t, time.Date(2023, 6, 2, 9, 0, 0, 0, auckland), w.NextStart())
+}
+
+func TestWithLocationSpringForwardDoesNotSkipAnHour(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	// Crosses midnight so the scan walks over the night of 2023-03-12,
+	// when clocks in New York jump from 02:00 EST straight to 03:00 EDT.
+	w, err := New(mkTime(23, 0), mkTime(4, 0), 0, 0, WithLocation(ny))
+	require.NoError(t, err)
+
+	w.Now = func() time.Time {
+		return time.Date(2023, 3, 12, 4, 30, 0, 0, time.UTC) // 2023-03-11 23:30 EST
+	}
+
+	wantEnd := time.Date(2023, 3, 12, 4, 0, 0, 0, ny)
+	assert.True(t, w.Active())
+	assert.Equal(t, wantEnd, w.NextEnd())
+	assert.Equal(t, wantEnd.Sub(w.Now().In(ny)), w.UntilEnd())
+}
+
+func TestWithLocationFallBackDoesNotDoubleCountAnHour(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	// Crosses midnight so the scan walks over the night of 2023-11-05,
+	// when clocks in New York fall back from 02:00 EDT to 01:00 EST,
+	// making the night 25 hours long instead of 24.
+	w, err := New(mkTime(23, 0), mkTime(4, 0), 0, 0, WithLocation(ny))
+	require.NoError(t, err)
+
+	w.Now = func() time.Time {
+		return time.Date(2023, 11, 5, 3, 30, 0, 0, time.UTC) // 2023-11-04 23:30 EDT
+	}
+
+	wantEnd := time.Date(2023, 11, 5, 4, 0, 0, 0, ny)
+	assert.True(t, w.Active())
+	assert.Equal(t, wantEnd, w.NextEnd())
+	assert.Equal(t, wantEnd.Sub(w.Now().In(ny)), w.UntilEnd())
+}
+
 func mkTime(hour, minute int) string {
 	return time.Date(1, 1, 1, hour, minute, 0, 0, time.UTC).Format(hourMinuteFormat)
 }
@@ -0,0 +1,215 @@
+// Copyright 2018 The Cacophony Project. All rights reserved.
+// Use of this source code is governed by the Apache License Version 2.0;
+// see the LICENSE file for further details.
+
+package window
+
+import "time"
+
+// Combinator describes how a WindowSet combines the active state of
+// its child windows.
+type Combinator int
+
+const (
+	// Union is active whenever any child window is active.
+	Union Combinator = iota
+	// Intersection is active only when every child window is active.
+	Intersection
+	// Difference is active when the first child window is active and
+	// none of the remaining child windows are.
+	Difference
+)
+
+// maxWindowSetBoundarySteps bounds how many child boundaries WindowSet
+// will walk through looking for a state transition, so a set of
+// windows that never reaches the state being searched for can't loop
+// forever.
+const maxWindowSetBoundarySteps = 4 * maxScheduleScanDays
+
+// WindowSet combines multiple Windows into a single active window
+// using a Combinator. The motivating use case is something like
+// "record between sunset-1h and sunrise+2h, but exclude 02:00-03:00
+// for maintenance", which is a Difference of two Windows, or "active
+// during either of two disjoint daily windows", a Union.
+type WindowSet struct {
+	Windows    []*Window
+	Combinator Combinator
+
+	// Now can be used to override the time source (for testing). Each
+	// child Window is evaluated at this time directly; WindowSet never
+	// overrides a child's own Now field, so sharing a Window between
+	// WindowSets (or reading it directly) while a query is in flight is
+	// safe.
+	Now func() time.Time
+}
+
+// NewWindowSet creates a WindowSet that combines windows using combinator.
+func NewWindowSet(combinator Combinator, windows ...*Window) *WindowSet {
+	return &WindowSet{
+		Windows:    windows,
+		Combinator: combinator,
+		Now:        time.Now,
+	}
+}
+
+// Active returns true if the WindowSet is currently active.
+func (ws *WindowSet) Active() bool {
+	return ws.combine(ws.statesAt(ws.Now()))
+}
+
+func (ws *WindowSet) statesAt(t time.Time) []bool {
+	states := make([]bool, len(ws.Windows))
+	for i, w := range ws.Windows {
+		if w.NoWindow {
+			states[i] = true
+			continue
+		}
+		states[i] = w.activeAt(w.toLocal(t))
+	}
+	return states
+}
+
+func (ws *WindowSet) activeAt(t time.Time) bool {
+	return ws.combine(ws.statesAt(t))
+}
+
+func (ws *WindowSet) combine(states []bool) bool {
+	switch ws.Combinator {
+	case Intersection:
+		if len(states) == 0 {
+			return false
+		}
+		for _, s := range states {
+			if !s {
+				return false
+			}
+		}
+		return true
+	case Difference:
+		if len(states) == 0 || !states[0] {
+			return false
+		}
+		for _, s := range states[1:] {
+			if s {
+				return false
+			}
+		}
+		return true
+	default: // Union
+		for _, s := range states {
+			if s {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// earliestBoundaryAfter returns the earliest NextStart/NextEnd among
+// all child windows, as evaluated from cursor, or the zero time if
+// none of the children have a future boundary.
+func (ws *WindowSet) earliestBoundaryAfter(cursor time.Time) time.Time {
+	var earliest time.Time
+	for _, w := range ws.Windows {
+		if w.NoWindow {
+			continue
+		}
+		anchor := w.toLocal(cursor)
+		for _, candidate := range []time.Time{w.nextStartFrom(anchor), w.nextEndFrom(anchor)} {
+			if candidate.IsZero() || !candidate.After(cursor) {
+				continue
+			}
+			if earliest.IsZero() || candidate.Before(earliest) {
+				earliest = candidate
+			}
+		}
+	}
+	return earliest
+}
+
+// walkToTransition scans forward through the boundaries of the child
+// windows, one at a time, until it finds the first point where the
+// set's active state becomes wantActive having not been so already.
+// It returns the zero time if no such transition is found within
+// maxWindowSetBoundarySteps.
+func (ws *WindowSet) walkToTransition(wantActive bool) time.Time {
+	cursor := ws.Now()
+	current := ws.activeAt(cursor)
+	for i := 0; i < maxWindowSetBoundarySteps; i++ {
+		next := ws.earliestBoundaryAfter(cursor)
+		if next.IsZero() {
+			return time.Time{}
+		}
+		state := ws.activeAt(next)
+		if current != wantActive && state == wantActive {
+			return next
+		}
+		cursor = next
+		current = state
+	}
+	return time.Time{}
+}
+
+// NextStart will give the next time the WindowSet will become active.
+func (ws *WindowSet) NextStart() time.Time {
+	return ws.walkToTransition(true)
+}
+
+// NextEnd will give the next time the WindowSet will stop being active.
+func (ws *WindowSet) NextEnd() time.Time {
+	return ws.walkToTransition(false)
+}
+
+// Until returns the duration until the WindowSet next becomes active.
+func (ws *WindowSet) Until() time.Duration {
+	if ws.Active() {
+		return time.Duration(0)
+	}
+	start := ws.NextStart()
+	if start.IsZero() {
+		return time.Duration(0)
+	}
+	if d := start.Sub(ws.Now()); d > 0 {
+		return d
+	}
+	return time.Duration(0)
+}
+
+// UntilEnd returns the duration until the WindowSet stops being active.
+func (ws *WindowSet) UntilEnd() time.Duration {
+	if !ws.Active() {
+		return time.Duration(0)
+	}
+	end := ws.NextEnd()
+	if end.IsZero() {
+		return time.Duration(0)
+	}
+	if d := end.Sub(ws.Now()); d > 0 {
+		return d
+	}
+	return time.Duration(0)
+}
+
+// UntilNextInterval gets when the next interval starts, taken as the
+// earliest such interval among the currently active child windows.
+// Only works when the WindowSet is currently active.
+func (ws *WindowSet) UntilNextInterval(interval time.Duration) time.Duration {
+	t := ws.Now()
+	if !ws.activeAt(t) {
+		return time.Duration(-1)
+	}
+	best := time.Duration(-1)
+	for _, w := range ws.Windows {
+		if w.NoWindow {
+			continue
+		}
+		anchor := w.toLocal(t)
+		if !w.activeAt(anchor) {
+			continue
+		}
+		if d := w.untilNextIntervalAt(anchor, interval); d >= 0 && (best < 0 || d < best) {
+			best = d
+		}
+	}
+	return best
+}
@@ -0,0 +1,154 @@
+// Copyright 2018 The Cacophony Project. All rights reserved.
+// Use of this source code is governed by the Apache License Version 2.0;
+// see the LICENSE file for further details.
+
+package window
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindowSetUnion(t *testing.T) {
+	// Two disjoint daily windows: 06:00-08:00 and 18:00-20:00.
+	morning, err := New(mkTime(6, 0), mkTime(8, 0), 0, 0)
+	require.NoError(t, err)
+	evening, err := New(mkTime(18, 0), mkTime(20, 0), 0, 0)
+	require.NoError(t, err)
+
+	ws := NewWindowSet(Union, morning, evening)
+	ws.Now = mkNow(7, 0)
+	assert.True(t, ws.Active())
+
+	ws.Now = mkNow(12, 0)
+	assert.False(t, ws.Active())
+	assert.Equal(t, mkNow(18, 0)(), ws.NextStart())
+
+	ws.Now = mkNow(19, 0)
+	assert.True(t, ws.Active())
+	assert.Equal(t, mkNow(20, 0)(), ws.NextEnd())
+}
+
+func TestWindowSetIntersection(t *testing.T) {
+	// Overlap of 09:00-17:00 and 12:00-20:00 is 12:00-17:00.
+	a, err := New(mkTime(9, 0), mkTime(17, 0), 0, 0)
+	require.NoError(t, err)
+	b, err := New(mkTime(12, 0), mkTime(20, 0), 0, 0)
+	require.NoError(t, err)
+
+	ws := NewWindowSet(Intersection, a, b)
+
+	ws.Now = mkNow(10, 0)
+	assert.False(t, ws.Active())
+	assert.Equal(t, mkNow(12, 0)(), ws.NextStart())
+
+	ws.Now = mkNow(14, 0)
+	assert.True(t, ws.Active())
+	assert.Equal(t, mkNow(17, 0)(), ws.NextEnd())
+
+	ws.Now = mkNow(18, 0)
+	assert.False(t, ws.Active())
+}
+
+func TestWindowSetIntersectionWithNoWindow(t *testing.T) {
+	// NoWindow is always active, so intersecting with it is a no-op.
+	always, err := New(mkTime(0, 0), mkTime(0, 0), 0, 0)
+	require.NoError(t, err)
+	require.True(t, always.NoWindow)
+	business, err := New(mkTime(9, 0), mkTime(17, 0), 0, 0)
+	require.NoError(t, err)
+
+	ws := NewWindowSet(Intersection, always, business)
+
+	ws.Now = mkNow(10, 0)
+	assert.True(t, ws.Active())
+
+	ws.Now = mkNow(20, 0)
+	assert.False(t, ws.Active())
+	business.Now = ws.Now
+	assert.Equal(t, business.NextStart(), ws.NextStart())
+}
+
+func TestWindowSetDifferenceExcludesMaintenanceWindow(t *testing.T) {
+	// Active 22:00-06:00, except 02:00-03:00 maintenance.
+	overnight, err := New(mkTime(22, 0), mkTime(6, 0), 0, 0)
+	require.NoError(t, err)
+	maintenance, err := New(mkTime(2, 0), mkTime(3, 0), 0, 0)
+	require.NoError(t, err)
+
+	ws := NewWindowSet(Difference, overnight, maintenance)
+
+	ws.Now = mkNow(23, 0)
+	assert.True(t, ws.Active())
+
+	ws.Now = mkNow(2, 30)
+	assert.False(t, ws.Active())
+	assert.Equal(t, mkNow(3, 0)(), ws.NextStart())
+
+	ws.Now = mkNow(4, 0)
+	assert.True(t, ws.Active())
+	assert.Equal(t, mkNow(6, 0)(), ws.NextEnd())
+}
+
+func TestWindowSetDifferenceAgainstNoWindowIsNeverActive(t *testing.T) {
+	// Subtracting an always-active NoWindow leaves nothing.
+	base, err := New(mkTime(9, 0), mkTime(17, 0), 0, 0)
+	require.NoError(t, err)
+	always, err := New(mkTime(0, 0), mkTime(0, 0), 0, 0)
+	require.NoError(t, err)
+	require.True(t, always.NoWindow)
+
+	ws := NewWindowSet(Difference, base, always)
+
+	ws.Now = mkNow(12, 0)
+	assert.False(t, ws.Active())
+	assert.Equal(t, time.Duration(0), ws.Until())
+	assert.Equal(t, time.Time{}, ws.NextStart())
+}
+
+func TestWindowSetNestedMidnightCrossings(t *testing.T) {
+	// a runs 20:00-04:00 (crosses midnight forward into the next day).
+	// b runs 22:00-02:00 (also crosses midnight, but a shorter span).
+	// Their union is simply a's span, since b is always inside it.
+	a, err := New(mkTime(20, 0), mkTime(4, 0), 0, 0)
+	require.NoError(t, err)
+	b, err := New(mkTime(22, 0), mkTime(2, 0), 0, 0)
+	require.NoError(t, err)
+
+	ws := NewWindowSet(Union, a, b)
+
+	ws.Now = mkNow(21, 0)
+	assert.True(t, ws.Active())
+
+	ws.Now = mkNow(3, 0)
+	assert.True(t, ws.Active())
+	assert.Equal(t, mkNow(4, 0)(), ws.NextEnd())
+
+	ws.Now = mkNow(10, 0)
+	assert.False(t, ws.Active())
+	assert.Equal(t, mkNow(20, 0)(), ws.NextStart())
+}
+
+func TestWindowSetDoesNotOverrideChildNow(t *testing.T) {
+	// A WindowSet must evaluate its children at its own Now without
+	// mutating child.Now, so a Window shared with another caller (or
+	// another WindowSet) isn't affected by a query in flight.
+	childNow := mkNow(9, 0)
+	child, err := New(mkTime(6, 0), mkTime(8, 0), 0, 0)
+	require.NoError(t, err)
+	child.Now = childNow
+
+	ws := NewWindowSet(Union, child)
+	ws.Now = mkNow(7, 0)
+
+	_ = ws.Active()
+	_ = ws.NextStart()
+	_ = ws.NextEnd()
+	_ = ws.UntilNextInterval(time.Hour)
+
+	assert.Equal(t, childNow(), child.Now())
+	assert.False(t, child.Active(), "child's own clock (09:00) should still see it as inactive")
+}